@@ -0,0 +1,148 @@
+// This file defines the Conn type and the connection/message-dispatch
+// plumbing the rest of this package depends on: establishing a socket,
+// assigning message IDs, and routing decoded responses back to the
+// goroutine that sent the request.
+
+package ldap
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/asn1-ber.v1"
+)
+
+// Conn represents an LDAP connection and the operations reachable from it.
+type Conn struct {
+	// Debug enables verbose request/response tracing through Debugf and
+	// DebugPacket.
+	Debug bool
+	// Debugger is the legacy destination for Debug output.
+	//
+	// Deprecated: call SetLogger with a Logger instead.
+	Debugger Debugger
+
+	logger           Logger
+	operationTimeout time.Duration
+
+	conn  net.Conn
+	isTLS bool
+
+	nextID     int64
+	contextsMu sync.Mutex
+	contexts   map[int64]*messageContext
+}
+
+// messageContext tracks an in-flight request awaiting its response.
+type messageContext struct {
+	id        int64
+	responses chan *PacketResponse
+}
+
+// PacketResponse wraps a decoded response packet, or the error that
+// prevented decoding it, delivered to a messageContext.
+type PacketResponse struct {
+	packet *ber.Packet
+	err    error
+}
+
+// ReadPacket returns the response packet, or the error encountered while
+// reading it.
+func (r *PacketResponse) ReadPacket() (*ber.Packet, error) {
+	return r.packet, r.err
+}
+
+// NewConn returns a new Conn using conn as its transport. isTLS records
+// whether conn is already a TLS session.
+func NewConn(conn net.Conn, isTLS bool) *Conn {
+	return &Conn{
+		conn:             conn,
+		isTLS:            isTLS,
+		contexts:         make(map[int64]*messageContext),
+		operationTimeout: DefaultOperationTimeout,
+	}
+}
+
+// Start begins reading responses from the connection in the background.
+func (l *Conn) Start() {
+	go l.readResponses()
+}
+
+func (l *Conn) readResponses() {
+	for {
+		packet, err := ber.ReadPacket(l.conn)
+		if err != nil {
+			return
+		}
+		if len(packet.Children) == 0 {
+			continue
+		}
+		id, ok := packet.Children[0].Value.(int64)
+		if !ok {
+			continue
+		}
+		l.contextsMu.Lock()
+		msgCtx, ok := l.contexts[id]
+		l.contextsMu.Unlock()
+		if !ok {
+			continue
+		}
+		msgCtx.responses <- &PacketResponse{packet: packet}
+	}
+}
+
+// nextMessageID returns the next LDAP message ID for this connection.
+func (l *Conn) nextMessageID() int64 {
+	return atomic.AddInt64(&l.nextID, 1)
+}
+
+// sendMessage writes packet to the connection and registers a
+// messageContext to receive its response.
+func (l *Conn) sendMessage(packet *ber.Packet) (*messageContext, error) {
+	msgCtx := &messageContext{
+		id:        packet.Children[0].Value.(int64),
+		responses: make(chan *PacketResponse, 1),
+	}
+	l.contextsMu.Lock()
+	l.contexts[msgCtx.id] = msgCtx
+	l.contextsMu.Unlock()
+
+	if _, err := l.conn.Write(packet.Bytes()); err != nil {
+		l.finishMessage(msgCtx)
+		return nil, NewError(ErrorNetwork, err)
+	}
+	return msgCtx, nil
+}
+
+// finishMessage stops tracking msgCtx's response channel.
+func (l *Conn) finishMessage(msgCtx *messageContext) {
+	l.contextsMu.Lock()
+	delete(l.contexts, msgCtx.id)
+	l.contextsMu.Unlock()
+}
+
+// Close closes the underlying connection.
+func (l *Conn) Close() error {
+	return l.conn.Close()
+}
+
+// Debugf logs a formatted debug message through l's Logger (see SetLogger)
+// when l.Debug is enabled.
+func (l *Conn) Debugf(format string, args ...interface{}) {
+	if !l.Debug {
+		return
+	}
+	l.getLogger().Debug(fmt.Sprintf(format, args...))
+}
+
+// DebugPacket logs packet through l's Logger at debug level when l.Debug is
+// enabled.
+func (l *Conn) DebugPacket(packet *ber.Packet) {
+	if !l.Debug {
+		return
+	}
+	l.getLogger().LogPacket(LevelDebug, packet)
+}
@@ -1,18 +1,127 @@
 package ldap
 
 import (
+	"fmt"
 	"log"
+	"log/slog"
 
 	"gopkg.in/asn1-ber.v1"
 )
 
-// Debugger is the interface that wraps the debug output methods
+// LogLevel identifies the severity of a log message or packet trace routed
+// through a Logger.
+type LogLevel int
+
+// The log levels a Logger can be called at, in increasing order of severity.
+const (
+	LevelTrace LogLevel = iota
+	LevelDebug
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// Logger is the interface this package uses for structured logging and
+// packet tracing. Implementations must be safe for concurrent use, since a
+// Conn may log from multiple goroutines.
+type Logger interface {
+	Trace(msg string, kv ...any)
+	Debug(msg string, kv ...any)
+	Info(msg string, kv ...any)
+	Warn(msg string, kv ...any)
+	Error(msg string, kv ...any)
+	// LogPacket logs a decoded BER packet, e.g. an LDAP request or
+	// response, at the given level. Implementations that want a raw dump
+	// can call ber.PrintPacket(packet) themselves.
+	LogPacket(level LogLevel, packet *ber.Packet)
+}
+
+// NopLogger discards everything logged through it. It is the effective
+// Logger for a Conn that has neither called SetLogger nor set the legacy
+// Debugger.
+type NopLogger struct{}
+
+// Trace implements Logger.
+func (NopLogger) Trace(string, ...any) {}
+
+// Debug implements Logger.
+func (NopLogger) Debug(string, ...any) {}
+
+// Info implements Logger.
+func (NopLogger) Info(string, ...any) {}
+
+// Warn implements Logger.
+func (NopLogger) Warn(string, ...any) {}
+
+// Error implements Logger.
+func (NopLogger) Error(string, ...any) {}
+
+// LogPacket implements Logger.
+func (NopLogger) LogPacket(LogLevel, *ber.Packet) {}
+
+// SlogLogger adapts a *slog.Logger to the Logger interface, mapping Trace to
+// slog's Debug level since slog has no lower level of its own.
+type SlogLogger struct {
+	Logger *slog.Logger
+}
+
+// NewSlogLogger wraps logger as a Logger.
+func NewSlogLogger(logger *slog.Logger) SlogLogger {
+	return SlogLogger{Logger: logger}
+}
+
+// Trace implements Logger.
+func (s SlogLogger) Trace(msg string, kv ...any) { s.Logger.Debug(msg, kv...) }
+
+// Debug implements Logger.
+func (s SlogLogger) Debug(msg string, kv ...any) { s.Logger.Debug(msg, kv...) }
+
+// Info implements Logger.
+func (s SlogLogger) Info(msg string, kv ...any) { s.Logger.Info(msg, kv...) }
+
+// Warn implements Logger.
+func (s SlogLogger) Warn(msg string, kv ...any) { s.Logger.Warn(msg, kv...) }
+
+// Error implements Logger.
+func (s SlogLogger) Error(msg string, kv ...any) { s.Logger.Error(msg, kv...) }
+
+// LogPacket implements Logger by logging a compact summary of packet; it
+// never dumps credentials, since BindRequest/PasswordModify content is not
+// part of the summary.
+func (s SlogLogger) LogPacket(level LogLevel, packet *ber.Packet) {
+	switch level {
+	case LevelTrace, LevelDebug:
+		s.Logger.Debug("ldap packet", "packet", packetSummary(packet))
+	case LevelInfo:
+		s.Logger.Info("ldap packet", "packet", packetSummary(packet))
+	case LevelWarn:
+		s.Logger.Warn("ldap packet", "packet", packetSummary(packet))
+	case LevelError:
+		s.Logger.Error("ldap packet", "packet", packetSummary(packet))
+	}
+}
+
+// packetSummary renders a compact, single-line description of packet for
+// structured log output. It intentionally doesn't walk into children, since
+// those may hold bind/modify credentials; use a Debugger/ber.PrintPacket for
+// a full BER dump instead.
+func packetSummary(packet *ber.Packet) string {
+	return fmt.Sprintf("%s (tag=%d, children=%d)", packet.Description, packet.Tag, len(packet.Children))
+}
+
+// Debugger is the legacy interface that wraps the debug output methods.
+//
+// Deprecated: implement Logger and install it with Conn.SetLogger instead.
+// Debugger values set on Conn.Debugger still work: Conn wraps them in a
+// Logger internally.
 type Debugger interface {
 	Printf(format string, args ...interface{})
 	PrintPacket(packet *ber.Packet)
 }
 
-// DefaultDebugger is the default implementation of the debug output methods
+// DefaultDebugger is the default implementation of the debug output methods.
+//
+// Deprecated: call Conn.SetLogger with a Logger (e.g. SlogLogger) instead.
 type DefaultDebugger struct{}
 
 // Printf write debug output
@@ -24,3 +133,78 @@ func (DefaultDebugger) Printf(format string, args ...interface{}) {
 func (DefaultDebugger) PrintPacket(packet *ber.Packet) {
 	ber.PrintPacket(packet)
 }
+
+// debuggerLogger adapts a legacy Debugger to the Logger interface so that
+// existing Debugger implementations keep working unmodified after Conn
+// starts routing through Logger.
+type debuggerLogger struct {
+	d Debugger
+}
+
+func (l debuggerLogger) Trace(msg string, kv ...any) { l.printf("TRACE", msg, kv...) }
+func (l debuggerLogger) Debug(msg string, kv ...any) { l.printf("DEBUG", msg, kv...) }
+func (l debuggerLogger) Info(msg string, kv ...any)  { l.printf("INFO", msg, kv...) }
+func (l debuggerLogger) Warn(msg string, kv ...any)  { l.printf("WARN", msg, kv...) }
+func (l debuggerLogger) Error(msg string, kv ...any) { l.printf("ERROR", msg, kv...) }
+
+func (l debuggerLogger) printf(level, msg string, kv ...any) {
+	if len(kv) == 0 {
+		l.d.Printf("[%s] %s", level, msg)
+		return
+	}
+	l.d.Printf("[%s] %s %v", level, msg, kv)
+}
+
+func (l debuggerLogger) LogPacket(level LogLevel, packet *ber.Packet) {
+	l.d.PrintPacket(packet)
+}
+
+// SetLogger installs logger as the destination for all of l's structured
+// logging and packet tracing, taking precedence over any legacy Debugger set
+// on l.Debugger.
+func (l *Conn) SetLogger(logger Logger) {
+	l.logger = logger
+}
+
+// getLogger returns the effective Logger for l: an explicitly installed
+// Logger, a shim around the legacy l.Debugger if one is set, or a NopLogger.
+func (l *Conn) getLogger() Logger {
+	if l.logger != nil {
+		return l.logger
+	}
+	if l.Debugger != nil {
+		return debuggerLogger{d: l.Debugger}
+	}
+	return NopLogger{}
+}
+
+// logResponsePacket decodes packet's LDAP descriptions and logs it through
+// l's Logger at debug level when l.Debug is enabled. It replaces the old
+// pattern of call sites reaching for addLDAPDescriptions/ber.PrintPacket
+// directly, which bypassed Debugger/Logger entirely.
+func (l *Conn) logResponsePacket(packet *ber.Packet) error {
+	if !l.Debug {
+		return nil
+	}
+	if err := addLDAPDescriptions(packet); err != nil {
+		return err
+	}
+	l.getLogger().LogPacket(LevelDebug, packet)
+	return nil
+}
+
+// Infof, Warnf and Errorf log through l's Logger at their respective
+// levels. Unlike Debugf/DebugPacket, they are not gated by l.Debug: they
+// report conditions (timeouts, abandons, network failures) that matter
+// whether or not verbose request/response tracing is turned on.
+func (l *Conn) Infof(format string, args ...interface{}) {
+	l.getLogger().Info(fmt.Sprintf(format, args...))
+}
+
+func (l *Conn) Warnf(format string, args ...interface{}) {
+	l.getLogger().Warn(fmt.Sprintf(format, args...))
+}
+
+func (l *Conn) Errorf(format string, args ...interface{}) {
+	l.getLogger().Error(fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,98 @@
+// This file contains the Modify DN operation as specified in section 4.9
+// of RFC 4511.
+//
+// https://tools.ietf.org/html/rfc4511#section-4.9
+//
+
+package ldap
+
+import (
+	"context"
+	"fmt"
+
+	"gopkg.in/asn1-ber.v1"
+)
+
+// ModifyDNRequest holds the parameters for a ModifyDN operation, used to
+// rename an entry's RDN and, optionally, move it to a new parent.
+type ModifyDNRequest struct {
+	// DN is the current distinguished name of the entry being renamed/moved
+	DN string
+	// NewRDN is the new relative distinguished name for the entry
+	NewRDN string
+	// DeleteOldRDN, if true, removes the attribute values that made up the
+	// old RDN from the entry
+	DeleteOldRDN bool
+	// NewSuperior, if non-empty, is the distinguished name of the new
+	// parent for the entry, moving it to a different part of the tree.
+	// If empty, the entry is renamed in place.
+	NewSuperior string
+}
+
+// NewModifyDNRequest creates a new ModifyDNRequest for the given dn, renaming
+// it to newRDN. If newSuperior is non-empty, the entry is also moved under
+// that new parent; an empty newSuperior renames the entry in place.
+func NewModifyDNRequest(dn, newRDN string, deleteOldRDN bool, newSuperior string) *ModifyDNRequest {
+	return &ModifyDNRequest{
+		DN:           dn,
+		NewRDN:       newRDN,
+		DeleteOldRDN: deleteOldRDN,
+		NewSuperior:  newSuperior,
+	}
+}
+
+func (req *ModifyDNRequest) encode() *ber.Packet {
+	packet := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ApplicationModifyDNRequest, nil, "Modify DN Request")
+	packet.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, req.DN, "DN"))
+	packet.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, req.NewRDN, "New RDN"))
+	packet.AppendChild(ber.NewBoolean(ber.ClassUniversal, ber.TypePrimitive, ber.TagBoolean, req.DeleteOldRDN, "Delete Old RDN"))
+	if req.NewSuperior != "" {
+		packet.AppendChild(ber.NewString(ber.ClassContext, ber.TypePrimitive, 0, req.NewSuperior, "New Superior"))
+	}
+	return packet
+}
+
+// ModifyDN renames the given entry and optionally moves it to a new parent.
+// It is equivalent to ModifyDNContext with a background context, so it
+// abandons the operation on the server only when a timeout set with
+// SetOperationTimeout expires before a response arrives.
+func (l *Conn) ModifyDN(modifyDNRequest *ModifyDNRequest) error {
+	return l.ModifyDNContext(context.Background(), modifyDNRequest)
+}
+
+// ModifyDNContext renames the given entry and optionally moves it to a new
+// parent, abandoning the operation on the server if ctx is done before a
+// response arrives.
+func (l *Conn) ModifyDNContext(ctx context.Context, modifyDNRequest *ModifyDNRequest) error {
+	ctx, cancel := l.withOperationTimeout(ctx)
+	defer cancel()
+
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Request")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, l.nextMessageID(), "MessageID"))
+	packet.AppendChild(modifyDNRequest.encode())
+
+	l.DebugPacket(packet)
+
+	msgCtx, err := l.sendMessage(packet)
+	if err != nil {
+		return err
+	}
+	defer l.finishMessage(msgCtx)
+
+	l.Debugf("%d: waiting for response", msgCtx.id)
+	packet, err = l.readResponsePacket(ctx, msgCtx)
+	if err != nil {
+		return err
+	}
+	l.Debugf("%d: got response %p", msgCtx.id, packet)
+
+	if err := l.logResponsePacket(packet); err != nil {
+		return err
+	}
+
+	if packet.Children[1].Tag == ApplicationModifyDNResponse {
+		return GetLDAPError(packet)
+	}
+
+	return NewError(ErrorUnexpectedResponse, fmt.Errorf("unexpected Response: %d", packet.Children[1].Tag))
+}
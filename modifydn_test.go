@@ -0,0 +1,49 @@
+package ldap
+
+import "testing"
+
+func TestModifyDNRequestEncode(t *testing.T) {
+	req := NewModifyDNRequest("cn=foo,dc=example,dc=com", "cn=bar", true, "")
+	packet := req.encode()
+
+	if packet.Tag != ApplicationModifyDNRequest {
+		t.Fatalf("packet.Tag = %d, want %d", packet.Tag, ApplicationModifyDNRequest)
+	}
+	if len(packet.Children) != 3 {
+		t.Fatalf("len(packet.Children) = %d, want 3 (no newSuperior)", len(packet.Children))
+	}
+	if got := packet.Children[0].Value.(string); got != req.DN {
+		t.Errorf("DN = %q, want %q", got, req.DN)
+	}
+	if got := packet.Children[1].Value.(string); got != req.NewRDN {
+		t.Errorf("NewRDN = %q, want %q", got, req.NewRDN)
+	}
+	if got := packet.Children[2].Value.(bool); got != req.DeleteOldRDN {
+		t.Errorf("DeleteOldRDN = %v, want %v", got, req.DeleteOldRDN)
+	}
+}
+
+func TestModifyDNRequestEncodeWithNewSuperior(t *testing.T) {
+	req := NewModifyDNRequest("cn=foo,dc=example,dc=com", "cn=bar", false, "dc=new,dc=example,dc=com")
+	packet := req.encode()
+
+	if len(packet.Children) != 4 {
+		t.Fatalf("len(packet.Children) = %d, want 4 (with newSuperior)", len(packet.Children))
+	}
+	newSuperior := packet.Children[3]
+	if newSuperior.Tag != 0 {
+		t.Errorf("newSuperior.Tag = %d, want 0 (context tag)", newSuperior.Tag)
+	}
+	if got := newSuperior.Value.(string); got != req.NewSuperior {
+		t.Errorf("NewSuperior = %q, want %q", got, req.NewSuperior)
+	}
+}
+
+func TestModifyDNRequestEncodeOmitsNewSuperiorWhenEmpty(t *testing.T) {
+	req := NewModifyDNRequest("cn=foo,dc=example,dc=com", "cn=bar", false, "")
+	packet := req.encode()
+
+	if len(packet.Children) != 3 {
+		t.Fatalf("len(packet.Children) = %d, want 3 when NewSuperior is empty", len(packet.Children))
+	}
+}
@@ -0,0 +1,119 @@
+package ldap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+)
+
+func TestNewNTLMNegotiateMessage(t *testing.T) {
+	msg := newNTLMNegotiateMessage("EXAMPLE")
+
+	if !bytes.Equal(msg[:8], []byte(ntlmSignature)) {
+		t.Fatalf("unexpected signature: %x", msg[:8])
+	}
+	if got := binary.LittleEndian.Uint32(msg[8:12]); got != ntlmTypeNegotiate {
+		t.Fatalf("message type = %d, want %d", got, ntlmTypeNegotiate)
+	}
+	if len(msg) != 32 {
+		t.Fatalf("negotiate message length = %d, want 32", len(msg))
+	}
+}
+
+func TestParseNTLMChallengeMessage(t *testing.T) {
+	serverChallenge := [8]byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08}
+	targetInfo := []byte{0x02, 0x00, 0x04, 0x00, 'E', 0x00, 'X', 0x00, 0x00, 0x00, 0x00, 0x00}
+
+	msg := make([]byte, 48+len(targetInfo))
+	copy(msg, ntlmSignature)
+	binary.LittleEndian.PutUint32(msg[8:], ntlmTypeChallenge)
+	copy(msg[24:32], serverChallenge[:])
+	binary.LittleEndian.PutUint16(msg[40:], uint16(len(targetInfo)))
+	binary.LittleEndian.PutUint16(msg[42:], uint16(len(targetInfo)))
+	binary.LittleEndian.PutUint32(msg[44:], 48)
+	copy(msg[48:], targetInfo)
+
+	challenge, err := parseNTLMChallengeMessage(msg)
+	if err != nil {
+		t.Fatalf("parseNTLMChallengeMessage() error = %v", err)
+	}
+	if challenge.serverChallenge != serverChallenge {
+		t.Fatalf("serverChallenge = %x, want %x", challenge.serverChallenge, serverChallenge)
+	}
+	if !bytes.Equal(challenge.targetInfo, targetInfo) {
+		t.Fatalf("targetInfo = %x, want %x", challenge.targetInfo, targetInfo)
+	}
+}
+
+func TestParseNTLMChallengeMessageRejectsBadInput(t *testing.T) {
+	if _, err := parseNTLMChallengeMessage([]byte("too short")); err == nil {
+		t.Fatal("expected error for truncated message")
+	}
+
+	negotiate := newNTLMNegotiateMessage("EXAMPLE")
+	padded := append(negotiate, make([]byte, 16)...)
+	if _, err := parseNTLMChallengeMessage(padded); err == nil {
+		t.Fatal("expected error when message type is NEGOTIATE, not CHALLENGE")
+	}
+}
+
+func TestNTHashIsDeterministicAnd16Bytes(t *testing.T) {
+	h1 := ntHash("Password1")
+	h2 := ntHash("Password1")
+	if !bytes.Equal(h1, h2) {
+		t.Fatal("ntHash is not deterministic for the same password")
+	}
+	if len(h1) != 16 {
+		t.Fatalf("ntHash length = %d, want 16", len(h1))
+	}
+	if bytes.Equal(h1, ntHash("Password2")) {
+		t.Fatal("ntHash should differ for different passwords")
+	}
+}
+
+func TestDecodeNTHashRoundTrip(t *testing.T) {
+	want := ntHash("hunter2")
+	got, err := decodeNTHash(hex.EncodeToString(want))
+	if err != nil {
+		t.Fatalf("decodeNTHash() error = %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("decodeNTHash() = %x, want %x", got, want)
+	}
+}
+
+func TestDecodeNTHashRejectsWrongLength(t *testing.T) {
+	if _, err := decodeNTHash("deadbeef"); err == nil {
+		t.Fatal("expected error for a hash that isn't 32 hex characters")
+	}
+}
+
+func TestUTF16LE(t *testing.T) {
+	got := utf16LE("AB")
+	want := []byte{'A', 0x00, 'B', 0x00}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("utf16LE(\"AB\") = %x, want %x", got, want)
+	}
+}
+
+func TestNTLMv2LMResponse(t *testing.T) {
+	ntlmv2Hash := make([]byte, 16)
+	serverChallenge := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	clientChallengeA := []byte{10, 20, 30, 40, 50, 60, 70, 80}
+	clientChallengeB := []byte{11, 20, 30, 40, 50, 60, 70, 80}
+
+	resp := ntlmv2LMResponse(ntlmv2Hash, serverChallenge, clientChallengeA)
+	if len(resp) != 24 {
+		t.Fatalf("LMv2 response length = %d, want 24", len(resp))
+	}
+	if !bytes.Equal(resp[16:], clientChallengeA) {
+		t.Fatalf("LMv2 response should end with the client challenge: got %x, want %x", resp[16:], clientChallengeA)
+	}
+	if !bytes.Equal(resp, ntlmv2LMResponse(ntlmv2Hash, serverChallenge, clientChallengeA)) {
+		t.Fatal("ntlmv2LMResponse is not deterministic for the same inputs")
+	}
+	if bytes.Equal(resp, ntlmv2LMResponse(ntlmv2Hash, serverChallenge, clientChallengeB)) {
+		t.Fatal("ntlmv2LMResponse should differ when the client challenge differs")
+	}
+}
@@ -6,7 +6,7 @@
 package ldap
 
 import (
-	"errors"
+	"context"
 	"fmt"
 
 	"gopkg.in/asn1-ber.v1"
@@ -14,6 +14,31 @@ import (
 
 const (
 	passwordModifyOID = "1.3.6.1.4.1.4203.1.11.1"
+
+	// passwordPolicyResponseOID is the responseControl OID for the Password
+	// Policy Response control, as defined in
+	// https://tools.ietf.org/html/draft-behera-ldap-password-policy-10
+	passwordPolicyResponseOID = "1.3.6.1.4.1.42.2.27.8.5.1"
+)
+
+// PasswordPolicyErrorNone indicates that PasswordModifyResult carries no
+// Password Policy Response error, either because the server did not return
+// the control or because it returned no error condition.
+const PasswordPolicyErrorNone = -1
+
+// Password policy error codes carried by the Password Policy Response
+// control, as defined in
+// https://tools.ietf.org/html/draft-behera-ldap-password-policy-10#section-6
+const (
+	PasswordExpired             = 0
+	AccountLocked               = 1
+	ChangeAfterReset            = 2
+	PasswordModNotAllowed       = 3
+	MustSupplyOldPassword       = 4
+	InsufficientPasswordQuality = 5
+	PasswordTooShort            = 6
+	PasswordTooYoung            = 7
+	PasswordInHistory           = 8
 )
 
 // PasswordModifyRequest implements the Password Modify Extended Operation as defined in https://www.ietf.org/rfc/rfc3062.txt
@@ -26,6 +51,9 @@ type PasswordModifyRequest struct {
 	OldPassword string
 	// NewPassword, if present, contains the desired password for this user
 	NewPassword string
+	// Controls holds any request controls to send alongside the operation,
+	// e.g. the PasswordPolicyRequest control
+	Controls []Control
 }
 
 // PasswordModifyResult holds the server response to a PasswordModifyRequest
@@ -34,6 +62,19 @@ type PasswordModifyResult struct {
 	GeneratedPassword string
 	// Referral are the returned referral
 	Referral string
+	// TimeBeforeExpiration holds the number of seconds before the user's
+	// password expires, if the server returned a Password Policy Response
+	// control with a timeBeforeExpiration warning
+	TimeBeforeExpiration int
+	// GraceAuthNsRemaining holds the number of grace logins still available
+	// to the user, if the server returned a Password Policy Response
+	// control with a graceAuthNsRemaining warning
+	GraceAuthNsRemaining int
+	// PasswordPolicyError holds one of the named password policy error
+	// constants (e.g. PasswordExpired, AccountLocked) if the server
+	// returned a Password Policy Response control with an error condition,
+	// or PasswordPolicyErrorNone otherwise
+	PasswordPolicyError int
 }
 
 func (r *PasswordModifyRequest) encode() (*ber.Packet, error) {
@@ -82,8 +123,22 @@ func NewPasswordModifyRequest(userIdentity string, oldPassword string, newPasswo
 	}
 }
 
-// PasswordModify performs the modification request
+// PasswordModify performs the modification request. It is equivalent to
+// PasswordModifyContext with a background context, so it abandons the
+// operation on the server only when a timeout set with SetOperationTimeout
+// expires before a response arrives.
 func (l *Conn) PasswordModify(passwordModifyRequest *PasswordModifyRequest) (*PasswordModifyResult, error) {
+	return l.PasswordModifyContext(context.Background(), passwordModifyRequest)
+}
+
+// PasswordModifyContext performs the modification request, abandoning the
+// operation on the server if ctx is done before a response arrives. See
+// Conn.SetOperationTimeout for applying a default deadline when ctx has no
+// deadline of its own.
+func (l *Conn) PasswordModifyContext(ctx context.Context, passwordModifyRequest *PasswordModifyRequest) (*PasswordModifyResult, error) {
+	ctx, cancel := l.withOperationTimeout(ctx)
+	defer cancel()
+
 	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Request")
 	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, l.nextMessageID(), "MessageID"))
 
@@ -93,6 +148,10 @@ func (l *Conn) PasswordModify(passwordModifyRequest *PasswordModifyRequest) (*Pa
 	}
 	packet.AppendChild(encodedPasswordModifyRequest)
 
+	if len(passwordModifyRequest.Controls) > 0 {
+		packet.AppendChild(encodeControls(passwordModifyRequest.Controls))
+	}
+
 	l.DebugPacket(packet)
 
 	msgCtx, err := l.sendMessage(packet)
@@ -101,41 +160,30 @@ func (l *Conn) PasswordModify(passwordModifyRequest *PasswordModifyRequest) (*Pa
 	}
 	defer l.finishMessage(msgCtx)
 
-	result := &PasswordModifyResult{}
+	result := &PasswordModifyResult{PasswordPolicyError: PasswordPolicyErrorNone}
 
 	l.Debugf("%d: waiting for response", msgCtx.id)
-	packetResponse, ok := <-msgCtx.responses
-	if !ok {
-		return nil, NewError(ErrorNetwork, errors.New("ldap: response channel closed"))
-	}
-	packet, err = packetResponse.ReadPacket()
-	l.Debugf("%d: got response %p", msgCtx.id, packet)
+	packet, err = l.readResponsePacket(ctx, msgCtx)
 	if err != nil {
 		return nil, err
 	}
+	l.Debugf("%d: got response %p", msgCtx.id, packet)
 
-	if packet == nil {
-		return nil, NewError(ErrorNetwork, errors.New("ldap: could not retrieve message"))
-	}
-
-	if l.Debug {
-		if err := addLDAPDescriptions(packet); err != nil {
-			return nil, err
-		}
-		ber.PrintPacket(packet)
+	if err := l.logResponsePacket(packet); err != nil {
+		return nil, err
 	}
 
+	var resultErr error
 	if packet.Children[1].Tag == ApplicationExtendedResponse {
-		err := GetLDAPError(packet)
-		if err != nil {
-			if IsErrorWithCode(err, LDAPResultReferral) {
+		resultErr = GetLDAPError(packet)
+		if resultErr != nil {
+			if IsErrorWithCode(resultErr, LDAPResultReferral) {
 				for _, child := range packet.Children[1].Children {
 					if child.Tag == 3 {
 						result.Referral = child.Children[0].Value.(string)
 					}
 				}
 			}
-			return result, err
 		}
 	} else {
 		return nil, NewError(ErrorUnexpectedResponse, fmt.Errorf("unexpected Response: %d", packet.Children[1].Tag))
@@ -153,5 +201,45 @@ func (l *Conn) PasswordModify(passwordModifyRequest *PasswordModifyRequest) (*Pa
 		}
 	}
 
-	return result, nil
+	if len(packet.Children) > 2 {
+		for _, control := range packet.Children[2].Children {
+			if len(control.Children) < 2 {
+				continue
+			}
+			if ber.DecodeString(control.Children[0].Data.Bytes()) != passwordPolicyResponseOID {
+				continue
+			}
+			for _, field := range control.Children[1:] {
+				if field.Tag != ber.TagOctetString {
+					continue // criticality BOOLEAN, not the controlValue
+				}
+				decodePasswordPolicyResponseValue(field.Data.Bytes(), result)
+			}
+		}
+	}
+
+	return result, resultErr
+}
+
+// decodePasswordPolicyResponseValue parses the controlValue of a Password
+// Policy Response control (OID 1.3.6.1.4.1.42.2.27.8.5.1) into result.
+func decodePasswordPolicyResponseValue(value []byte, result *PasswordModifyResult) {
+	responseValue := ber.DecodePacket(value)
+	for _, child := range responseValue.Children {
+		switch child.Tag {
+		case 0: // warning, a CHOICE explicitly tagged [0]
+			if len(child.Children) != 1 {
+				continue
+			}
+			warning := child.Children[0]
+			switch warning.Tag {
+			case 0:
+				result.TimeBeforeExpiration = int(ber.DecodeInteger(warning.Data.Bytes()))
+			case 1:
+				result.GraceAuthNsRemaining = int(ber.DecodeInteger(warning.Data.Bytes()))
+			}
+		case 1: // error, an ENUMERATED tagged [1]
+			result.PasswordPolicyError = int(ber.DecodeInteger(child.Data.Bytes()))
+		}
+	}
 }
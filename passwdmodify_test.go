@@ -0,0 +1,79 @@
+package ldap
+
+import (
+	"testing"
+
+	"gopkg.in/asn1-ber.v1"
+)
+
+// buildPasswordPolicyControlValue hand-builds the controlValue bytes of a
+// Password Policy Response control (OID 1.3.6.1.4.1.42.2.27.8.5.1) for
+// testing decodePasswordPolicyResponseValue without a server.
+func buildPasswordPolicyControlValue(warningTag int, warningValue int64, includeError bool, errorValue int64) []byte {
+	seq := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "PasswordPolicyResponseValue")
+	if warningTag >= 0 {
+		warning := ber.Encode(ber.ClassContext, ber.TypeConstructed, 0, nil, "warning")
+		warning.AppendChild(ber.NewInteger(ber.ClassContext, ber.TypePrimitive, warningTag, warningValue, "warning value"))
+		seq.AppendChild(warning)
+	}
+	if includeError {
+		seq.AppendChild(ber.NewInteger(ber.ClassContext, ber.TypePrimitive, 1, errorValue, "error"))
+	}
+	return seq.Bytes()
+}
+
+func TestDecodePasswordPolicyResponseValueExpirationWarning(t *testing.T) {
+	value := buildPasswordPolicyControlValue(0, 3600, false, 0)
+	result := &PasswordModifyResult{PasswordPolicyError: PasswordPolicyErrorNone}
+
+	decodePasswordPolicyResponseValue(value, result)
+
+	if result.TimeBeforeExpiration != 3600 {
+		t.Errorf("TimeBeforeExpiration = %d, want 3600", result.TimeBeforeExpiration)
+	}
+	if result.GraceAuthNsRemaining != 0 {
+		t.Errorf("GraceAuthNsRemaining = %d, want 0", result.GraceAuthNsRemaining)
+	}
+	if result.PasswordPolicyError != PasswordPolicyErrorNone {
+		t.Errorf("PasswordPolicyError = %d, want PasswordPolicyErrorNone", result.PasswordPolicyError)
+	}
+}
+
+func TestDecodePasswordPolicyResponseValueGraceLogins(t *testing.T) {
+	value := buildPasswordPolicyControlValue(1, 2, false, 0)
+	result := &PasswordModifyResult{PasswordPolicyError: PasswordPolicyErrorNone}
+
+	decodePasswordPolicyResponseValue(value, result)
+
+	if result.GraceAuthNsRemaining != 2 {
+		t.Errorf("GraceAuthNsRemaining = %d, want 2", result.GraceAuthNsRemaining)
+	}
+	if result.TimeBeforeExpiration != 0 {
+		t.Errorf("TimeBeforeExpiration = %d, want 0", result.TimeBeforeExpiration)
+	}
+}
+
+func TestDecodePasswordPolicyResponseValueError(t *testing.T) {
+	value := buildPasswordPolicyControlValue(-1, 0, true, MustSupplyOldPassword)
+	result := &PasswordModifyResult{PasswordPolicyError: PasswordPolicyErrorNone}
+
+	decodePasswordPolicyResponseValue(value, result)
+
+	if result.PasswordPolicyError != MustSupplyOldPassword {
+		t.Errorf("PasswordPolicyError = %d, want MustSupplyOldPassword", result.PasswordPolicyError)
+	}
+}
+
+func TestDecodePasswordPolicyResponseValueEmpty(t *testing.T) {
+	value := buildPasswordPolicyControlValue(-1, 0, false, 0)
+	result := &PasswordModifyResult{PasswordPolicyError: PasswordPolicyErrorNone}
+
+	decodePasswordPolicyResponseValue(value, result)
+
+	if result.PasswordPolicyError != PasswordPolicyErrorNone {
+		t.Errorf("PasswordPolicyError = %d, want PasswordPolicyErrorNone", result.PasswordPolicyError)
+	}
+	if result.TimeBeforeExpiration != 0 || result.GraceAuthNsRemaining != 0 {
+		t.Errorf("expected no warning fields set, got TimeBeforeExpiration=%d GraceAuthNsRemaining=%d", result.TimeBeforeExpiration, result.GraceAuthNsRemaining)
+	}
+}